@@ -0,0 +1,135 @@
+package wavefront
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+)
+
+const (
+	drainRetryInitialBackoff = 500 * time.Millisecond
+	drainRetryMaxBackoff     = 30 * time.Second
+
+	// drainMaxPermanentRetries bounds how many times a span that the sender
+	// classifies as a permanent failure (errorTypeTag(err) == "permanent")
+	// is retried before it's dead-lettered (logged and skipped) so one bad
+	// span can't wedge the partition forever. Retryable failures (proxy
+	// hiccups, transient network errors) are retried without limit.
+	drainMaxPermanentRetries = 5
+)
+
+// DrainKafkaSink reads spanEnvelope messages off topic and forwards each one
+// to sender.SendSpan, undoing the buffering done by KafkaSink. It blocks
+// until ctx is cancelled or the consumer group errors out.
+//
+// This is meant to run as a standalone process (or sidecar) between a
+// KafkaSink-fronted exporter and the real Wavefront proxy, so a proxy
+// outage only backs up Kafka rather than dropping spans.
+func DrainKafkaSink(ctx context.Context, brokers []string, topic, group string, sender senders.Sender, logError func(msg string, err error)) error {
+	cfg := sarama.NewConfig()
+	cfg.Consumer.Return.Errors = true
+
+	consumerGroup, err := sarama.NewConsumerGroup(brokers, group, cfg)
+	if err != nil {
+		return err
+	}
+	defer consumerGroup.Close()
+
+	// cfg.Consumer.Return.Errors routes consumer-group errors onto this
+	// channel instead of sarama's default of logging them; if nobody reads
+	// it, it fills up and errors are dropped silently. Drain it for as long
+	// as the consumer group runs.
+	go func() {
+		for err := range consumerGroup.Errors() {
+			logError("Kafka consumer group error", err)
+		}
+	}()
+
+	handler := &kafkaDrainHandler{
+		sender:          sender,
+		logError:        logError,
+		initialBackoff:  drainRetryInitialBackoff,
+		maxBackoff:      drainRetryMaxBackoff,
+		maxPermanentTry: drainMaxPermanentRetries,
+	}
+	for {
+		if err := consumerGroup.Consume(ctx, []string{topic}, handler); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+type kafkaDrainHandler struct {
+	sender   senders.Sender
+	logError func(msg string, err error)
+
+	// initialBackoff, maxBackoff, and maxPermanentTry mirror the
+	// drainRetry*/drainMaxPermanentRetries constants; they're plumbed
+	// through as fields (rather than read directly) so tests can shrink
+	// the backoff and retry budget without sleeping for real.
+	initialBackoff  time.Duration
+	maxBackoff      time.Duration
+	maxPermanentTry int
+}
+
+func (h *kafkaDrainHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *kafkaDrainHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *kafkaDrainHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var env spanEnvelope
+		if err := json.Unmarshal(msg.Value, &env); err != nil {
+			h.logError("Error decoding buffered span", err)
+			sess.MarkMessage(msg, "")
+			continue
+		}
+
+		if sessionEnded := h.forwardWithBoundedRetry(sess.Context(), env); sessionEnded {
+			return nil
+		}
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// forwardWithBoundedRetry sends env via h.sender, retrying on failure with
+// capped exponential backoff until it succeeds, the span is dead-lettered
+// (dropped and logged) once h.maxPermanentTry retries of permanent failures
+// are used up, or ctx is cancelled. It reports whether ctx ended the retry loop, in which
+// case the caller must leave the Kafka offset uncommitted so the next owner
+// of the partition redelivers the span instead of losing it.
+func (h *kafkaDrainHandler) forwardWithBoundedRetry(ctx context.Context, env spanEnvelope) (sessionEnded bool) {
+	backoff := h.initialBackoff
+	permanentRetries := 0
+	for {
+		err := h.sender.SendSpan(env.Name, env.StartMillis, env.DurationMillis, env.Source,
+			env.TraceID, env.SpanID, env.Parents, env.FollowsFrom, env.Tags, env.SpanLogs)
+		if err == nil {
+			return false
+		}
+		if errorTypeTag(err) == "permanent" {
+			permanentRetries++
+			if permanentRetries > h.maxPermanentTry {
+				h.logError("Dropping buffered span after repeated permanent send errors", err)
+				return false
+			}
+		}
+		h.logError("Error forwarding buffered span, will retry without committing the offset", err)
+
+		select {
+		case <-ctx.Done():
+			return true
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > h.maxBackoff {
+			backoff = h.maxBackoff
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package wavefront
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+)
+
+// fakeDrainSender embeds a nil senders.Sender so it satisfies the interface
+// (calling any method other than SendSpan panics) and lets tests script
+// SendSpan's return values.
+type fakeDrainSender struct {
+	senders.Sender
+	errs  []error
+	calls int
+}
+
+func (f *fakeDrainSender) SendSpan(name string, startMillis, durationMillis int64, source, traceID, spanID string,
+	parents, followsFrom []string, tags []senders.SpanTag, spanLogs []senders.SpanLog) error {
+	var err error
+	if f.calls < len(f.errs) {
+		err = f.errs[f.calls]
+	}
+	f.calls++
+	return err
+}
+
+func newTestDrainHandler(sender senders.Sender) *kafkaDrainHandler {
+	return &kafkaDrainHandler{
+		sender:          sender,
+		logError:        func(string, error) {},
+		initialBackoff:  time.Millisecond,
+		maxBackoff:      time.Millisecond,
+		maxPermanentTry: 2,
+	}
+}
+
+type temporaryNetError struct{}
+
+func (temporaryNetError) Error() string   { return "temporary net error" }
+func (temporaryNetError) Timeout() bool   { return false }
+func (temporaryNetError) Temporary() bool { return true }
+
+var _ net.Error = temporaryNetError{}
+
+func TestForwardWithBoundedRetryRetriesRetryableUntilSuccess(t *testing.T) {
+	sender := &fakeDrainSender{errs: []error{temporaryNetError{}, temporaryNetError{}, nil}}
+	h := newTestDrainHandler(sender)
+
+	if sessionEnded := h.forwardWithBoundedRetry(context.Background(), spanEnvelope{}); sessionEnded {
+		t.Fatalf("forwardWithBoundedRetry reported sessionEnded, want it to succeed")
+	}
+	if sender.calls != 3 {
+		t.Fatalf("SendSpan called %d times, want 3 (two retries then success)", sender.calls)
+	}
+}
+
+func TestForwardWithBoundedRetryDropsAfterMaxPermanentRetries(t *testing.T) {
+	permanentErr := errors.New("bad tag")
+	sender := &fakeDrainSender{errs: []error{permanentErr, permanentErr, permanentErr, permanentErr, permanentErr}}
+	h := newTestDrainHandler(sender)
+
+	if sessionEnded := h.forwardWithBoundedRetry(context.Background(), spanEnvelope{}); sessionEnded {
+		t.Fatalf("forwardWithBoundedRetry reported sessionEnded, want it to dead-letter and return")
+	}
+	if want := h.maxPermanentTry + 1; sender.calls != want {
+		t.Fatalf("SendSpan called %d times, want %d (maxPermanentTry+1 attempts before dead-lettering)", sender.calls, want)
+	}
+}
+
+func TestForwardWithBoundedRetryStopsOnContextCancel(t *testing.T) {
+	sender := &fakeDrainSender{errs: []error{temporaryNetError{}, temporaryNetError{}, temporaryNetError{}}}
+	h := newTestDrainHandler(sender)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if sessionEnded := h.forwardWithBoundedRetry(ctx, spanEnvelope{}); !sessionEnded {
+		t.Fatalf("forwardWithBoundedRetry reported sessionEnded = false, want true for a cancelled context")
+	}
+}
@@ -0,0 +1,94 @@
+package wavefront
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/Shopify/sarama"
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+)
+
+// sinkNameKafka tags wavefront/sink_dropped samples coming from a KafkaSink.
+const sinkNameKafka = "kafka"
+
+// spanEnvelope is the wire format produced to Kafka. It carries exactly the
+// fields processSpan would otherwise hand straight to a senders.Sender, so
+// the consumer side can replay them verbatim.
+type spanEnvelope struct {
+	Name           string            `json:"name"`
+	StartMillis    int64             `json:"startMillis"`
+	DurationMillis int64             `json:"durationMillis"`
+	Source         string            `json:"source"`
+	TraceID        string            `json:"traceId"`
+	SpanID         string            `json:"spanId"`
+	Parents        []string          `json:"parents,omitempty"`
+	FollowsFrom    []string          `json:"followsFrom,omitempty"`
+	Tags           []senders.SpanTag `json:"tags,omitempty"`
+	SpanLogs       []senders.SpanLog `json:"spanLogs,omitempty"`
+}
+
+// KafkaSink produces spans to a Kafka topic instead of sending them to
+// Wavefront directly, letting ops buffer in front of a flaky proxy. Pair it
+// with DrainKafkaSink to forward the topic on to a senders.Sender.
+type KafkaSink struct {
+	producer sarama.SyncProducer
+	topic    string
+	dropped  uint64
+}
+
+// NewKafkaSink builds a KafkaSink that produces to topic over brokers using
+// cfg (pass nil to use sarama's defaults with producer acks required).
+func NewKafkaSink(brokers []string, topic string, cfg *sarama.Config) (*KafkaSink, error) {
+	if cfg == nil {
+		cfg = sarama.NewConfig()
+		cfg.Producer.Return.Successes = true
+		cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	}
+	producer, err := sarama.NewSyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaSink{producer: producer, topic: topic}, nil
+}
+
+func (k *KafkaSink) SendSpan(name string, startMillis, durationMillis int64, source, traceID, spanID string,
+	parents, followsFrom []string, tags []senders.SpanTag, spanLogs []senders.SpanLog) error {
+	payload, err := json.Marshal(spanEnvelope{
+		Name:           name,
+		StartMillis:    startMillis,
+		DurationMillis: durationMillis,
+		Source:         source,
+		TraceID:        traceID,
+		SpanID:         spanID,
+		Parents:        parents,
+		FollowsFrom:    followsFrom,
+		Tags:           tags,
+		SpanLogs:       spanLogs,
+	})
+	if err != nil {
+		atomic.AddUint64(&k.dropped, 1)
+		recordSinkDropped(context.Background(), sinkNameKafka, k.Dropped())
+		return err
+	}
+
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: k.topic,
+		Key:   sarama.StringEncoder(traceID),
+		Value: sarama.ByteEncoder(payload),
+	})
+	if err != nil {
+		atomic.AddUint64(&k.dropped, 1)
+		recordSinkDropped(context.Background(), sinkNameKafka, k.Dropped())
+	}
+	return err
+}
+
+func (k *KafkaSink) Dropped() uint64 {
+	return atomic.LoadUint64(&k.dropped)
+}
+
+// Close releases the underlying Kafka producer.
+func (k *KafkaSink) Close() error {
+	return k.producer.Close()
+}
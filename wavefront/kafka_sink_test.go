@@ -0,0 +1,69 @@
+package wavefront
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+)
+
+func TestSpanEnvelopeRoundTrip(t *testing.T) {
+	want := spanEnvelope{
+		Name:           "my-span",
+		StartMillis:    1000,
+		DurationMillis: 42,
+		Source:         "my-source",
+		TraceID:        "00000000-0000-0000-0000-000000000001",
+		SpanID:         "00000000-0000-0000-0000-000000000002",
+		Parents:        []string{"00000000-0000-0000-0000-000000000003"},
+		FollowsFrom:    []string{"00000000-0000-0000-0000-000000000004"},
+		Tags:           []senders.SpanTag{{Key: "http.method", Value: "GET"}},
+		SpanLogs: []senders.SpanLog{{
+			Timestamp: 1000,
+			Fields:    map[string]string{"message": "boom"},
+		}},
+	}
+
+	payload, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got spanEnvelope
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("round trip mismatch:\n got:  %+v\n want: %+v", got, want)
+	}
+}
+
+func TestSpanEnvelopeRoundTripNoReferences(t *testing.T) {
+	// A root span has no parents/follows-from/tags/logs; make sure the
+	// omitempty fields don't round-trip as non-nil empty slices, which
+	// would otherwise trip up callers doing an equality check.
+	want := spanEnvelope{
+		Name:           "root-span",
+		StartMillis:    1000,
+		DurationMillis: 1,
+		Source:         "my-source",
+		TraceID:        "00000000-0000-0000-0000-000000000001",
+		SpanID:         "00000000-0000-0000-0000-000000000002",
+	}
+
+	payload, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got spanEnvelope
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("round trip mismatch:\n got:  %+v\n want: %+v", got, want)
+	}
+}
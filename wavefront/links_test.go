@@ -0,0 +1,52 @@
+package wavefront
+
+import (
+	"testing"
+
+	"go.opencensus.io/trace"
+)
+
+func TestClassifyLink(t *testing.T) {
+	selfTrace := trace.TraceID{1}
+	otherTrace := trace.TraceID{2}
+
+	cases := []struct {
+		name string
+		link trace.Link
+		want linkRoute
+	}{
+		{
+			name: "same-trace parent link becomes a Wavefront parent reference",
+			link: trace.Link{TraceID: selfTrace, Type: trace.LinkTypeParent},
+			want: linkRouteParent,
+		},
+		{
+			name: "same-trace child link becomes a Wavefront follows-from reference",
+			link: trace.Link{TraceID: selfTrace, Type: trace.LinkTypeChild},
+			want: linkRouteFollowsFrom,
+		},
+		{
+			name: "unspecified link type falls back to a log",
+			link: trace.Link{TraceID: selfTrace, Type: trace.LinkTypeUnspecified},
+			want: linkRouteLog,
+		},
+		{
+			name: "cross-trace parent link falls back to a log, not a bogus same-trace parent",
+			link: trace.Link{TraceID: otherTrace, Type: trace.LinkTypeParent},
+			want: linkRouteLog,
+		},
+		{
+			name: "cross-trace child link falls back to a log",
+			link: trace.Link{TraceID: otherTrace, Type: trace.LinkTypeChild},
+			want: linkRouteLog,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyLink(selfTrace, tc.link); got != tc.want {
+				t.Fatalf("classifyLink(%+v) = %v, want %v", tc.link, got, tc.want)
+			}
+		})
+	}
+}
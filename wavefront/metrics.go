@@ -0,0 +1,127 @@
+package wavefront
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// Tag keys used by the exporter's self-observability views.
+var (
+	keyErrorType, _ = tag.NewKey("error_type")
+	keySink, _      = tag.NewKey("sink")
+)
+
+// Measures recorded by processSpan and the goroutine that wraps
+// SpanSink.SendSpan, except mSinkDropped, which the sinks record themselves
+// via recordSinkDropped. They're unexported: users interact with them through
+// the Views below and RegisterViews.
+var (
+	mSpansSent    = stats.Int64("wavefront/spans_sent", "Number of spans successfully handed off to a sink", stats.UnitDimensionless)
+	mSpansDropped = stats.Int64("wavefront/spans_dropped", "Number of spans dropped before reaching a sink", stats.UnitDimensionless)
+	mSendErrors   = stats.Int64("wavefront/send_errors", "Number of errors returned by SpanSink.SendSpan", stats.UnitDimensionless)
+	mSendLatency  = stats.Float64("wavefront/send_latency", "Latency of SpanSink.SendSpan calls", stats.UnitMilliseconds)
+	mQueueDepth   = stats.Int64("wavefront/queue_depth", "Number of send commands currently queued", stats.UnitDimensionless)
+	mTagsPerSpan  = stats.Int64("wavefront/tags_per_span", "Number of Wavefront span tags attached to a span", stats.UnitDimensionless)
+	mSinkDropped  = stats.Int64("wavefront/sink_dropped", "Cumulative spans a SpanSink has dropped, per sink (see SinkStats)", stats.UnitDimensionless)
+)
+
+// Views exposing the measures above. Register them with RegisterViews, or
+// cherry-pick a subset with view.Register directly.
+var (
+	SpansSentView = &view.View{
+		Name: "wavefront/spans_sent", Measure: mSpansSent,
+		Description: "Count of spans successfully handed off to a sink",
+		Aggregation: view.Count(),
+	}
+	SpansDroppedView = &view.View{
+		Name: "wavefront/spans_dropped", Measure: mSpansDropped,
+		Description: "Count of spans dropped before reaching a sink",
+		Aggregation: view.Count(),
+	}
+	SendErrorsView = &view.View{
+		Name: "wavefront/send_errors", Measure: mSendErrors,
+		Description: "Count of SpanSink.SendSpan errors, by error_type",
+		TagKeys:     []tag.Key{keyErrorType},
+		Aggregation: view.Count(),
+	}
+	SendLatencyView = &view.View{
+		Name: "wavefront/send_latency", Measure: mSendLatency,
+		Description: "Distribution of SpanSink.SendSpan call latency",
+		Aggregation: view.Distribution(0, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000),
+	}
+	QueueDepthView = &view.View{
+		Name: "wavefront/queue_depth", Measure: mQueueDepth,
+		Description: "Number of send commands currently queued",
+		Aggregation: view.LastValue(),
+	}
+	TagsPerSpanView = &view.View{
+		Name: "wavefront/tags_per_span", Measure: mTagsPerSpan,
+		Description: "Distribution of Wavefront span tags attached per span",
+		Aggregation: view.Distribution(0, 1, 2, 4, 8, 16, 32, 64, 128),
+	}
+	SinkDroppedView = &view.View{
+		Name: "wavefront/sink_dropped", Measure: mSinkDropped,
+		Description: "Cumulative spans dropped by each SpanSink, by sink",
+		TagKeys:     []tag.Key{keySink},
+		Aggregation: view.LastValue(),
+	}
+)
+
+// RegisterViews registers every view above with the default OpenCensus
+// stats exporter(s), including Wavefront itself if one has been wired up as
+// a stats exporter for self-hosted dashboards.
+func RegisterViews() error {
+	return view.Register(
+		SpansSentView,
+		SpansDroppedView,
+		SendErrorsView,
+		SendLatencyView,
+		QueueDepthView,
+		TagsPerSpanView,
+		SinkDroppedView,
+	)
+}
+
+// recordSinkDropped publishes a SpanSink's current cumulative drop count
+// (SinkStats.Dropped) as the wavefront/sink_dropped gauge, tagged by sink
+// name, so each sink's drops are visible as their own series instead of
+// being folded into the single exporter-wide spans_dropped counter.
+func recordSinkDropped(ctx context.Context, sink string, total uint64) {
+	ctx, err := tag.New(ctx, tag.Insert(keySink, sink))
+	if err != nil {
+		return
+	}
+	stats.Record(ctx, mSinkDropped.M(int64(total)))
+}
+
+// errorTypeTag classifies err into a small, bounded set of values so the
+// send_errors view doesn't explode into one series per distinct error
+// message. Temporary/timeout errors (proxy hiccups, transient network
+// issues) are "retryable"; everything else is "permanent".
+func errorTypeTag(err error) string {
+	if err == nil {
+		return ""
+	}
+	if netErr, ok := err.(net.Error); ok && (netErr.Timeout() || netErr.Temporary()) { //nolint:staticcheck // Temporary is deprecated but still the best signal senders gives us
+		return "retryable"
+	}
+	return "permanent"
+}
+
+func recordSendSpan(ctx context.Context, start time.Time, err error) {
+	latencyMs := float64(time.Since(start)) / float64(time.Millisecond)
+	stats.Record(ctx, mSendLatency.M(latencyMs))
+	if err != nil {
+		ctx, tagErr := tag.New(ctx, tag.Insert(keyErrorType, errorTypeTag(err)))
+		if tagErr == nil {
+			stats.Record(ctx, mSendErrors.M(1))
+		}
+		return
+	}
+	stats.Record(ctx, mSpansSent.M(1))
+}
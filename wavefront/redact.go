@@ -0,0 +1,63 @@
+package wavefront
+
+import "unicode/utf8"
+
+const truncationSuffix = "...(truncated)"
+
+// Redactor rewrites or drops an attribute/annotation/message-event field
+// before it's serialized into a Wavefront tag or log. Returning false drops
+// the field entirely.
+type Redactor func(key string, value interface{}) (string, bool)
+
+// redactField runs key/value through the exporter's allow-list (or, when no
+// allow-list is set, its deny-list), Redactor and max-length truncation, in
+// that order. It's the single choke point applied uniformly to span
+// attributes, annotation attributes, message-event fields, and link
+// attributes, so a value that leaks a token, email or query string can't
+// bypass it by going through one of those paths but not another. Annotation
+// messages, the status message and the tags the exporter builds itself
+// (span kind, error, sampling, link IDs) are not passed through it.
+//
+// The returned bool is false if the field should be dropped.
+func (e *Exporter) redactField(key string, value interface{}) (string, bool) {
+	if !e.attributeAllowed(key) {
+		return "", false
+	}
+
+	val := serialize(value)
+	if e.Redactor != nil {
+		var ok bool
+		val, ok = e.Redactor(key, value)
+		if !ok {
+			return "", false
+		}
+	}
+
+	return e.truncateValue(val), true
+}
+
+func (e *Exporter) attributeAllowed(key string) bool {
+	if len(e.AttributeAllowList) > 0 {
+		_, ok := e.AttributeAllowList[key]
+		return ok
+	}
+	if len(e.AttributeDenyList) > 0 {
+		_, denied := e.AttributeDenyList[key]
+		return !denied
+	}
+	return true
+}
+
+func (e *Exporter) truncateValue(val string) string {
+	max := e.MaxAttributeValueLength
+	if max <= 0 || len(val) <= max {
+		return val
+	}
+	// Cut on a rune boundary so multi-byte UTF-8 sequences (e.g. non-ASCII
+	// names in emails) aren't split into invalid UTF-8.
+	cut := max
+	for cut > 0 && !utf8.RuneStart(val[cut]) {
+		cut--
+	}
+	return val[:cut] + truncationSuffix
+}
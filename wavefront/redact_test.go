@@ -0,0 +1,103 @@
+package wavefront
+
+import "testing"
+
+func TestRedactField(t *testing.T) {
+	cases := []struct {
+		name     string
+		exporter *Exporter
+		key      string
+		value    interface{}
+		wantVal  string
+		wantKeep bool
+	}{
+		{
+			name:     "no config passes through",
+			exporter: &Exporter{},
+			key:      "http.url",
+			value:    "https://example.com",
+			wantVal:  "https://example.com",
+			wantKeep: true,
+		},
+		{
+			name:     "allow-list drops keys not listed",
+			exporter: &Exporter{AttributeAllowList: map[string]struct{}{"http.method": {}}},
+			key:      "authorization",
+			value:    "Bearer secret",
+			wantKeep: false,
+		},
+		{
+			name:     "allow-list keeps listed keys",
+			exporter: &Exporter{AttributeAllowList: map[string]struct{}{"http.method": {}}},
+			key:      "http.method",
+			value:    "GET",
+			wantVal:  "GET",
+			wantKeep: true,
+		},
+		{
+			name:     "deny-list drops listed keys",
+			exporter: &Exporter{AttributeDenyList: map[string]struct{}{"email": {}}},
+			key:      "email",
+			value:    "user@example.com",
+			wantKeep: false,
+		},
+		{
+			name: "redactor rewrite",
+			exporter: &Exporter{Redactor: func(key string, value interface{}) (string, bool) {
+				return "REDACTED", true
+			}},
+			key:      "token",
+			value:    "sk-live-abc",
+			wantVal:  "REDACTED",
+			wantKeep: true,
+		},
+		{
+			name: "redactor drop",
+			exporter: &Exporter{Redactor: func(key string, value interface{}) (string, bool) {
+				return "", false
+			}},
+			key:      "token",
+			value:    "sk-live-abc",
+			wantKeep: false,
+		},
+		{
+			name:     "truncation appends marker",
+			exporter: &Exporter{MaxAttributeValueLength: 4},
+			key:      "note",
+			value:    "abcdefgh",
+			wantVal:  "abcd" + truncationSuffix,
+			wantKeep: true,
+		},
+		{
+			name:     "truncation backs off to a rune boundary",
+			exporter: &Exporter{MaxAttributeValueLength: 4},
+			key:      "email",
+			value:    "josé@example.com", // a 4-byte cut would land inside the 2-byte 'é'
+			wantVal:  "jos" + truncationSuffix,
+			wantKeep: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, keep := tc.exporter.redactField(tc.key, tc.value)
+			if keep != tc.wantKeep {
+				t.Fatalf("redactField(%q) keep = %v, want %v", tc.key, keep, tc.wantKeep)
+			}
+			if keep && val != tc.wantVal {
+				t.Fatalf("redactField(%q) = %q, want %q", tc.key, val, tc.wantVal)
+			}
+		})
+	}
+}
+
+func TestRedactFieldAppliesDenyListToMessageEventFields(t *testing.T) {
+	// The allow/deny gate applies to the message-event keys (MsgID,
+	// MsgType, ...) processSpan synthesizes itself just as it does to
+	// attributes — there's no exemption for them.
+	e := &Exporter{AttributeDenyList: map[string]struct{}{msgIDKey: {}}}
+
+	if _, keep := e.redactField(msgIDKey, "abc-123"); keep {
+		t.Fatalf("redactField(%s) was kept despite an AttributeDenyList listing it", msgIDKey)
+	}
+}
@@ -0,0 +1,46 @@
+package wavefront
+
+import (
+	"encoding/binary"
+
+	"go.opencensus.io/trace"
+)
+
+const (
+	// samplingModulus is the range the low 8 bytes of a trace ID are
+	// reduced into before being compared against SamplerRate. Using the
+	// trace ID (rather than a random draw) keeps the decision identical
+	// for every span in the same trace.
+	samplingModulus = 1e6
+
+	samplingPriorityKey = "sampling.priority"
+	samplingRateKey     = "sampling.rate"
+)
+
+// sample decides whether sd should be kept. keep is false only when the
+// span was rejected by the probabilistic sampler and no tail-based rule
+// forced it through. forced reports whether a tail-based rule (error
+// status or latency) kept the span; those rules are checked first, so a
+// forced span is kept whatever the probabilistic decision would have been.
+func (e *Exporter) sample(sd *trace.SpanData) (keep, forced bool, rate float64) {
+	rate = e.SamplerRate
+	if rate <= 0 {
+		// No SamplerRate configured: preserve the exporter's historical
+		// behavior of sending everything the OpenCensus sampler accepted.
+		return true, false, 1
+	}
+	if rate >= 1 {
+		return true, false, rate
+	}
+
+	if sd.Status.Code != trace.StatusCodeOK {
+		return true, true, rate
+	}
+	if threshold := e.LatencyThreshold; threshold > 0 && sd.EndTime.Sub(sd.StartTime) >= threshold {
+		return true, true, rate
+	}
+
+	low8 := binary.BigEndian.Uint64(sd.TraceID[8:])
+	keep = low8%samplingModulus < uint64(rate*samplingModulus)
+	return keep, false, rate
+}
@@ -0,0 +1,77 @@
+package wavefront
+
+import (
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+func spanData(traceID trace.TraceID, statusCode int32, duration time.Duration) *trace.SpanData {
+	start := time.Unix(0, 0)
+	return &trace.SpanData{
+		SpanContext: trace.SpanContext{TraceID: traceID},
+		StartTime:   start,
+		EndTime:     start.Add(duration),
+		Status:      trace.Status{Code: statusCode},
+	}
+}
+
+func TestSampleDeterministicPerTrace(t *testing.T) {
+	e := &Exporter{SamplerRate: 0.5}
+	traceID := trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8}
+	sd := spanData(traceID, int32(trace.StatusCodeOK), time.Millisecond)
+
+	first, _, _ := e.sample(sd)
+	for i := 0; i < 10; i++ {
+		keep, _, _ := e.sample(sd)
+		if keep != first {
+			t.Fatalf("sample(%x) flip-flopped across calls: got %v, want %v", traceID, keep, first)
+		}
+	}
+}
+
+func TestSampleUnconfiguredKeepsEverything(t *testing.T) {
+	e := &Exporter{} // SamplerRate zero value: sampling disabled
+	for i := byte(0); i < 8; i++ {
+		traceID := trace.TraceID{}
+		traceID[15] = i
+		sd := spanData(traceID, int32(trace.StatusCodeOK), time.Millisecond)
+		if keep, forced, _ := e.sample(sd); !keep || forced {
+			t.Fatalf("sample with no SamplerRate configured = (%v, %v), want (true, false)", keep, forced)
+		}
+	}
+}
+
+func TestSampleRateOneKeepsEverything(t *testing.T) {
+	e := &Exporter{SamplerRate: 1}
+	traceID := trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	sd := spanData(traceID, int32(trace.StatusCodeOK), time.Millisecond)
+	if keep, _, _ := e.sample(sd); !keep {
+		t.Fatalf("sample with SamplerRate=1 dropped a span")
+	}
+}
+
+func TestSampleTailKeepsErrorsRegardlessOfRate(t *testing.T) {
+	e := &Exporter{SamplerRate: 0.0001}
+	// A trace ID whose low 8 bytes are large relative to the modulus, so
+	// the probabilistic decision alone would reject it.
+	traceID := trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	sd := spanData(traceID, int32(trace.StatusCodeUnknown), time.Millisecond)
+
+	keep, forced, _ := e.sample(sd)
+	if !keep || !forced {
+		t.Fatalf("sample(error span) = (%v, %v), want (true, true)", keep, forced)
+	}
+}
+
+func TestSampleTailKeepsSlowSpansRegardlessOfRate(t *testing.T) {
+	e := &Exporter{SamplerRate: 0.0001, LatencyThreshold: 100 * time.Millisecond}
+	traceID := trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	sd := spanData(traceID, int32(trace.StatusCodeOK), 200*time.Millisecond)
+
+	keep, forced, _ := e.sample(sd)
+	if !keep || !forced {
+		t.Fatalf("sample(slow span) = (%v, %v), want (true, true)", keep, forced)
+	}
+}
@@ -0,0 +1,79 @@
+package wavefront
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+)
+
+// sinkNameWavefront tags wavefront/sink_dropped samples coming from a
+// wavefrontSink.
+const sinkNameWavefront = "wavefront"
+
+// SpanSink is the destination for a fully-assembled span. It mirrors
+// senders.Sender.SendSpan so the existing Wavefront sender can be used as a
+// SpanSink without any adapting, while other implementations (e.g. a
+// durable Kafka buffer) can sit in front of or alongside it.
+type SpanSink interface {
+	SendSpan(name string, startMillis, durationMillis int64, source, traceID, spanID string,
+		parents, followsFrom []string, tags []senders.SpanTag, spanLogs []senders.SpanLog) error
+}
+
+// SinkStats is implemented by SpanSinks that track their own drop counts.
+// Each such sink publishes Dropped() to the wavefront/sink_dropped view
+// (tagged by sink name) whenever it drops a span, so per-sink counters are
+// visible instead of only the single exporter-wide spans_dropped figure.
+type SinkStats interface {
+	Dropped() uint64
+}
+
+// wavefrontSink adapts a senders.Sender to SpanSink, counting any send
+// errors as drops for that sink.
+type wavefrontSink struct {
+	sender  senders.Sender
+	dropped uint64
+}
+
+// NewWavefrontSink wraps sender as a SpanSink.
+func NewWavefrontSink(sender senders.Sender) SpanSink {
+	return &wavefrontSink{sender: sender}
+}
+
+func (s *wavefrontSink) SendSpan(name string, startMillis, durationMillis int64, source, traceID, spanID string,
+	parents, followsFrom []string, tags []senders.SpanTag, spanLogs []senders.SpanLog) error {
+	err := s.sender.SendSpan(name, startMillis, durationMillis, source, traceID, spanID, parents, followsFrom, tags, spanLogs)
+	if err != nil {
+		atomic.AddUint64(&s.dropped, 1)
+		recordSinkDropped(context.Background(), sinkNameWavefront, s.Dropped())
+	}
+	return err
+}
+
+func (s *wavefrontSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// teeSink fans a span out to every underlying sink. Each sink tracks its
+// own drops (see SinkStats); teeSink itself only surfaces the first error
+// so callers can still log something went wrong.
+type teeSink struct {
+	sinks []SpanSink
+}
+
+// Tee composes multiple SpanSinks into one, e.g. to send every span to both
+// Wavefront directly and a Kafka buffer.
+func Tee(sinks ...SpanSink) SpanSink {
+	return &teeSink{sinks: sinks}
+}
+
+func (t *teeSink) SendSpan(name string, startMillis, durationMillis int64, source, traceID, spanID string,
+	parents, followsFrom []string, tags []senders.SpanTag, spanLogs []senders.SpanLog) error {
+	var firstErr error
+	for _, sink := range t.sinks {
+		if err := sink.SendSpan(name, startMillis, durationMillis, source, traceID, spanID, parents, followsFrom, tags, spanLogs); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -2,13 +2,16 @@ package wavefront
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"strconv"
 	"sync/atomic"
+	"time"
 
 	"github.com/wavefronthq/wavefront-sdk-go/senders"
 
+	"go.opencensus.io/stats"
 	"go.opencensus.io/trace"
 )
 
@@ -26,6 +29,10 @@ const (
 	msgTypeKey       = "MsgType"
 	msgCmpSzKey      = "MsgCompressedByteSize"
 	msgUcmpSzKey     = "MsgUncompressedByteSize"
+	linkEventKey     = "link"
+	linkTypeKey      = "LinkType"
+	linkTraceIDKey   = "LinkTraceID"
+	linkSpanIDKey    = "LinkSpanID"
 )
 
 var (
@@ -64,14 +71,30 @@ var (
 		"sent",
 		"received",
 	}
+
+	linkTypeStrings = [...]string{
+		"unspecified",
+		"child",
+		"parent",
+	}
 )
 
 func (e *Exporter) processSpan(sd *trace.SpanData) {
+	atomic.AddUint64(&e.spansSampled, 1)
+	keep, forced, rate := e.sample(sd)
+	if !keep {
+		atomic.AddUint64(&e.spansDroppedBySampler, 1)
+		return
+	}
+	atomic.AddUint64(&e.spansKept, 1)
+
 	// Span Tags
 	appTags := e.appMap
-	spanTags := make([](senders.SpanTag), 0, 3+len(sd.Attributes)+len(appTags))
+	spanTags := make([](senders.SpanTag), 0, 5+len(sd.Attributes)+len(appTags))
 	for k, v := range sd.Attributes {
-		spanTags = append(spanTags, senders.SpanTag{Key: k, Value: serialize(v)})
+		if val, ok := e.redactField(k, v); ok {
+			spanTags = append(spanTags, senders.SpanTag{Key: k, Value: val})
+		}
 	}
 	for k, v := range appTags {
 		spanTags = append(spanTags, senders.SpanTag{Key: k, Value: v})
@@ -90,6 +113,19 @@ func (e *Exporter) processSpan(sd *trace.SpanData) {
 		)
 	}
 
+	if e.SamplerRate > 0 {
+		priority := "0"
+		if forced {
+			priority = "1"
+		}
+		spanTags = append(spanTags,
+			senders.SpanTag{Key: samplingPriorityKey, Value: priority},
+			senders.SpanTag{Key: samplingRateKey, Value: serialize(rate)},
+		)
+	}
+
+	stats.Record(context.Background(), mTagsPerSpan.M(int64(len(spanTags))))
+
 	// Sort span tags by Keys?
 	// sort.SliceStable(spanTags, func(i1, i2 int) bool { return spanTags[i1].Key < spanTags[i2].Key })
 
@@ -110,7 +146,9 @@ func (e *Exporter) processSpan(sd *trace.SpanData) {
 		annoTags := make(map[string]string, 1+len(a.Attributes))
 		annoTags[annoMsgKey] = a.Message
 		for k, v := range a.Attributes {
-			annoTags[k] = serialize(v)
+			if val, ok := e.redactField(k, v); ok {
+				annoTags[k] = val
+			}
 		}
 		spanLogs = append(spanLogs, senders.SpanLog{
 			Timestamp: a.Time.UnixNano() / nanoToMillis,
@@ -118,11 +156,17 @@ func (e *Exporter) processSpan(sd *trace.SpanData) {
 		})
 	}
 	for _, m := range sd.MessageEvents {
-		meTags := map[string]string{
-			msgIDKey:     serialize(m.MessageID),
+		meFields := map[string]interface{}{
+			msgIDKey:     m.MessageID,
 			msgTypeKey:   enumString(int(m.EventType), msgEventStrings[:]),
-			msgCmpSzKey:  serialize(m.CompressedByteSize),
-			msgUcmpSzKey: serialize(m.UncompressedByteSize),
+			msgCmpSzKey:  m.CompressedByteSize,
+			msgUcmpSzKey: m.UncompressedByteSize,
+		}
+		meTags := make(map[string]string, len(meFields))
+		for k, v := range meFields {
+			if val, ok := e.redactField(k, v); ok {
+				meTags[k] = val
+			}
 		}
 		spanLogs = append(spanLogs, senders.SpanLog{
 			Timestamp: m.Time.UnixNano() / nanoToMillis,
@@ -134,26 +178,101 @@ func (e *Exporter) processSpan(sd *trace.SpanData) {
 	endTime := sd.EndTime.Sub(sd.StartTime).Nanoseconds() / nanoToMillis
 	traceID := convertTraceID(sd.TraceID)
 	spanID := convertSpanID(sd.SpanID)
-	var parents []string
+	var parents, followsFrom []string
 	pspanBytes := [8]byte(sd.ParentSpanID)
 	if !bytes.Equal(zeroSpanID[:], pspanBytes[:]) { //don't add parent in case of root span
 		parents = []string{convertSpanID(sd.ParentSpanID)}
 	}
 
+	links := sd.Links
+	if max := e.MaxLinksPerSpan; max > 0 && len(links) > max {
+		links = links[:max]
+	}
+	for _, link := range links {
+		switch classifyLink(sd.TraceID, link) {
+		case linkRouteParent:
+			parents = append(parents, convertSpanID(link.SpanID))
+		case linkRouteFollowsFrom:
+			followsFrom = append(followsFrom, convertSpanID(link.SpanID))
+		default:
+			linkTags := make(map[string]string, 4+len(link.Attributes))
+			linkTags[spanLogEventKey] = linkEventKey
+			linkTags[linkTypeKey] = enumString(int(link.Type), linkTypeStrings[:])
+			linkTags[linkTraceIDKey] = convertTraceID(link.TraceID)
+			linkTags[linkSpanIDKey] = convertSpanID(link.SpanID)
+			for k, v := range link.Attributes {
+				if val, ok := e.redactField(k, v); ok {
+					linkTags[k] = val
+				}
+			}
+			spanLogs = append(spanLogs, senders.SpanLog{
+				Timestamp: startTime,
+				Fields:    linkTags,
+			})
+		}
+	}
+
 	cmd := func() {
-		defer e.semRelease()
+		defer func() {
+			stats.Record(context.Background(), mQueueDepth.M(atomic.AddInt64(&e.queueDepth, -1)))
+			e.semRelease()
+		}()
 
-		e.logError("Error sending span", e.sender.SendSpan(
+		sendStart := time.Now()
+		err := e.sink.SendSpan(
 			sd.Name,
 			startTime, endTime,
 			e.Source,
-			traceID, spanID, parents, nil,
+			traceID, spanID, parents, followsFrom,
 			spanTags, spanLogs,
-		))
+		)
+		recordSendSpan(context.Background(), sendStart, err)
+		e.logError("Error sending span", err)
 	}
 
+	// Record the depth increment before handing cmd to queueCmd: cmd's
+	// paired decrement (in its defer) can start running as soon as
+	// queueCmd returns, and QueueDepthView is a LastValue gauge, so
+	// recording the -1 before its +1 would show a bogus reading.
+	stats.Record(context.Background(), mQueueDepth.M(atomic.AddInt64(&e.queueDepth, 1)))
 	if !e.queueCmd(cmd) {
 		atomic.AddUint64(&e.spansDropped, 1)
+		stats.Record(context.Background(), mSpansDropped.M(1))
+		stats.Record(context.Background(), mQueueDepth.M(atomic.AddInt64(&e.queueDepth, -1)))
+		return
+	}
+}
+
+// linkRoute is where a trace.Link ends up in a Wavefront span: a parent
+// reference, a follows-from reference, or (the zero value) a log entry.
+type linkRoute int
+
+const (
+	linkRouteLog linkRoute = iota
+	linkRouteParent
+	linkRouteFollowsFrom
+)
+
+// classifyLink decides how a trace.Link attached to the span identified by
+// selfTraceID should be represented in Wavefront. Only same-trace links map
+// cleanly onto Wavefront's parent/follows-from references; everything else
+// (cross-trace links, LinkTypeUnspecified) falls back to a log entry.
+func classifyLink(selfTraceID trace.TraceID, link trace.Link) linkRoute {
+	if link.TraceID != selfTraceID {
+		return linkRouteLog
+	}
+	switch link.Type {
+	case trace.LinkTypeParent:
+		// The linked span is this span's parent: that's exactly Wavefront's
+		// CHILD_OF reference.
+		return linkRouteParent
+	case trace.LinkTypeChild:
+		// The linked span is this span's child; there's no "I have a
+		// child" reference in Wavefront's model, so follows-from is the
+		// closest fit.
+		return linkRouteFollowsFrom
+	default:
+		return linkRouteLog
 	}
 }
 